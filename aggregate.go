@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// aggSpec is one parsed entry of the -agg flag, e.g. sum(Price) or count().
+type aggSpec struct {
+	Func  string // "count", "sum", "avg", "min", or "max"
+	Field string // empty for count()
+}
+
+// Label is the header name this aggregate is emitted under.
+func (s aggSpec) Label() string {
+	if s.Field == "" {
+		return s.Func
+	}
+	return s.Func + "_" + s.Field
+}
+
+// parseAggSpecs parses a -agg flag value such as
+// "count();sum(Price);avg(Price);min(Age);max(Age)".
+func parseAggSpecs(spec string) ([]aggSpec, error) {
+	var specs []aggSpec
+	if spec == "" {
+		return specs, nil
+	}
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		open := strings.Index(part, "(")
+		if open < 0 || !strings.HasSuffix(part, ")") {
+			return nil, fmt.Errorf("invalid -agg entry %q (want func(field), e.g. sum(Price))", part)
+		}
+		fn := part[:open]
+		field := strings.TrimSpace(part[open+1 : len(part)-1])
+		switch fn {
+		case "count":
+		case "sum", "avg", "min", "max":
+			if field == "" {
+				return nil, fmt.Errorf("%s() requires a field argument", fn)
+			}
+		default:
+			return nil, fmt.Errorf("unknown aggregate function %q", fn)
+		}
+		specs = append(specs, aggSpec{Func: fn, Field: field})
+	}
+	return specs, nil
+}
+
+// validateAggSpecs rejects sum()/avg() over a non-numeric column up
+// front, rather than silently summing/averaging to 0. min()/max() have no
+// such restriction: they fall back to lexicographic comparison for
+// non-numeric columns.
+func validateAggSpecs(specs []aggSpec, types map[string]ColumnType) error {
+	for _, spec := range specs {
+		if spec.Func != "sum" && spec.Func != "avg" {
+			continue
+		}
+		switch types[spec.Field].Kind {
+		case KindInt, KindFloat:
+		default:
+			return fmt.Errorf("cannot apply %s() to field %q: declare it as -types %s:int or %s:float", spec.Func, spec.Field, spec.Field, spec.Field)
+		}
+	}
+	return nil
+}
+
+// aggAccumulator holds the running state of one aggSpec for one group.
+// sum/avg track a Welford-style running mean alongside the running sum, so
+// avg stays numerically stable over very long streams.
+type aggAccumulator struct {
+	spec      aggSpec
+	count     int64
+	sum       float64
+	mean      float64
+	min       Value
+	max       Value
+	hasMinMax bool
+}
+
+func newAggAccumulator(spec aggSpec) *aggAccumulator {
+	return &aggAccumulator{spec: spec}
+}
+
+func (a *aggAccumulator) update(record Record) {
+	switch a.spec.Func {
+	case "count":
+		a.count++
+	case "sum", "avg":
+		v := record.Data[a.spec.Field]
+		if !v.Valid {
+			return
+		}
+		x := numericValue(v)
+		a.count++
+		a.sum += x
+		a.mean += (x - a.mean) / float64(a.count)
+	case "min":
+		v := record.Data[a.spec.Field]
+		if !v.Valid {
+			return
+		}
+		if !a.hasMinMax || compareValues(v, a.min) < 0 {
+			a.min, a.hasMinMax = v, true
+		}
+	case "max":
+		v := record.Data[a.spec.Field]
+		if !v.Valid {
+			return
+		}
+		if !a.hasMinMax || compareValues(v, a.max) > 0 {
+			a.max, a.hasMinMax = v, true
+		}
+	}
+}
+
+func (a *aggAccumulator) result() Value {
+	switch a.spec.Func {
+	case "count":
+		return Value{Kind: KindInt, Str: strconv.FormatInt(a.count, 10), Int: a.count, Valid: true}
+	case "sum":
+		return Value{Kind: KindFloat, Str: strconv.FormatFloat(a.sum, 'g', -1, 64), Float: a.sum, Valid: true}
+	case "avg":
+		return Value{Kind: KindFloat, Str: strconv.FormatFloat(a.mean, 'g', -1, 64), Float: a.mean, Valid: true}
+	case "min":
+		return a.min
+	case "max":
+		return a.max
+	}
+	return Value{}
+}
+
+func numericValue(v Value) float64 {
+	switch v.Kind {
+	case KindInt:
+		return float64(v.Int)
+	case KindFloat:
+		return v.Float
+	}
+	return 0
+}
+
+// aggregateRecords buckets records from in by their group-by key tuple
+// (joined with \x1f, following the repo's existing separator convention)
+// and reduces each bucket with its own copy of specs, emitting one record
+// per group in first-seen order. When groupBy is empty, it produces a
+// single summary row over the whole input.
+func aggregateRecords(in <-chan Record, groupBy []string, specs []aggSpec) []Record {
+	type group struct {
+		key  []Value
+		accs []*aggAccumulator
+	}
+
+	groups := make(map[string]*group)
+	var order []string
+
+	// With no group-by key, always emit a single summary row, even over
+	// zero matching records (e.g. count() should read 0, not be absent).
+	if len(groupBy) == 0 {
+		accs := make([]*aggAccumulator, len(specs))
+		for i, spec := range specs {
+			accs[i] = newAggAccumulator(spec)
+		}
+		groups[""] = &group{accs: accs}
+		order = append(order, "")
+	}
+
+	for record := range in {
+		keyValues := make([]Value, len(groupBy))
+		keyParts := make([]string, len(groupBy))
+		for i, field := range groupBy {
+			keyValues[i] = record.Data[field]
+			keyParts[i] = keyValues[i].Str
+		}
+		key := strings.Join(keyParts, "\x1f")
+
+		g, ok := groups[key]
+		if !ok {
+			accs := make([]*aggAccumulator, len(specs))
+			for i, spec := range specs {
+				accs[i] = newAggAccumulator(spec)
+			}
+			g = &group{key: keyValues, accs: accs}
+			groups[key] = g
+			order = append(order, key)
+		}
+		for _, acc := range g.accs {
+			acc.update(record)
+		}
+	}
+
+	results := make([]Record, 0, len(order))
+	for _, key := range order {
+		g := groups[key]
+		data := make(map[string]Value, len(groupBy)+len(specs))
+		for i, field := range groupBy {
+			data[field] = g.key[i]
+		}
+		for _, acc := range g.accs {
+			data[acc.spec.Label()] = acc.result()
+		}
+		results = append(results, Record{Data: data})
+	}
+
+	return results
+}
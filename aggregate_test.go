@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestAggregateRecordsMinMaxString(t *testing.T) {
+	types := map[string]ColumnType{"City": {Kind: KindString}}
+	cities := []string{"Stockholm", "Boras", "Abisko"}
+
+	in := make(chan Record, len(cities))
+	for _, city := range cities {
+		in <- Record{Data: map[string]Value{"City": parseValue(city, types["City"])}}
+	}
+	close(in)
+
+	specs, err := parseAggSpecs("min(City);max(City)")
+	if err != nil {
+		t.Fatalf("parseAggSpecs: %v", err)
+	}
+
+	results := aggregateRecords(in, nil, specs)
+	if len(results) != 1 {
+		t.Fatalf("got %d result rows, want 1", len(results))
+	}
+
+	got := results[0].Data["min_City"].String()
+	if got != "Abisko" {
+		t.Errorf("min_City = %q, want %q", got, "Abisko")
+	}
+	got = results[0].Data["max_City"].String()
+	if got != "Stockholm" {
+		t.Errorf("max_City = %q, want %q", got, "Stockholm")
+	}
+}
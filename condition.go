@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fieldMatch reports whether a single cell's typed Value satisfies a
+// filter condition.
+type fieldMatch func(Value) bool
+
+// parseFieldCondition compiles a filter value into a fieldMatch. Besides
+// the glob/regex/negated patterns from compilePattern, typed columns
+// accept comparison operators: >, >=, <, <=, !=, between(lo,hi), and
+// in(v1,v2,...).
+func parseFieldCondition(field, rawValue string, types map[string]ColumnType) (fieldMatch, error) {
+	ct := types[field]
+	value := strings.TrimSpace(rawValue)
+
+	switch {
+	case strings.HasPrefix(value, ">="):
+		return compareCondition(ct, field, ">=", value[2:])
+	case strings.HasPrefix(value, "<="):
+		return compareCondition(ct, field, "<=", value[2:])
+	case strings.HasPrefix(value, "!="):
+		return compareCondition(ct, field, "!=", value[2:])
+	case strings.HasPrefix(value, ">"):
+		return compareCondition(ct, field, ">", value[1:])
+	case strings.HasPrefix(value, "<"):
+		return compareCondition(ct, field, "<", value[1:])
+	case strings.HasPrefix(value, "between(") && strings.HasSuffix(value, ")"):
+		return betweenCondition(ct, field, value[len("between("):len(value)-1])
+	case strings.HasPrefix(value, "in(") && strings.HasSuffix(value, ")"):
+		return inCondition(ct, field, value[len("in("):len(value)-1])
+	default:
+		m, err := compilePattern(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(v Value) bool { return m(v.Str) }, nil
+	}
+}
+
+// requireOrdered rejects comparison operators against string columns,
+// since "greater than" on free text isn't a meaningful operation here.
+func requireOrdered(ct ColumnType, field, op string) error {
+	if ct.Kind == KindString {
+		return fmt.Errorf("cannot apply %q to field %q: declare its type with -types to compare it numerically, by date, or as a bool", op, field)
+	}
+	return nil
+}
+
+func compareCondition(ct ColumnType, field, op, rawOperand string) (fieldMatch, error) {
+	if err := requireOrdered(ct, field, op); err != nil {
+		return nil, err
+	}
+	operand := parseValue(strings.TrimSpace(rawOperand), ct)
+	if !operand.Valid {
+		return nil, fmt.Errorf("value %q is not a valid %s for field %q", rawOperand, op, field)
+	}
+	return func(v Value) bool {
+		if !v.Valid {
+			return false
+		}
+		cmp := compareValues(v, operand)
+		switch op {
+		case ">":
+			return cmp > 0
+		case ">=":
+			return cmp >= 0
+		case "<":
+			return cmp < 0
+		case "<=":
+			return cmp <= 0
+		case "!=":
+			return cmp != 0
+		}
+		return false
+	}, nil
+}
+
+func betweenCondition(ct ColumnType, field, rawArgs string) (fieldMatch, error) {
+	if err := requireOrdered(ct, field, "between"); err != nil {
+		return nil, err
+	}
+	args := strings.SplitN(rawArgs, ",", 2)
+	if len(args) != 2 {
+		return nil, fmt.Errorf("between() for field %q needs two comma-separated bounds", field)
+	}
+	lo := parseValue(strings.TrimSpace(args[0]), ct)
+	hi := parseValue(strings.TrimSpace(args[1]), ct)
+	if !lo.Valid || !hi.Valid {
+		return nil, fmt.Errorf("between() bounds %q are not valid values for field %q", rawArgs, field)
+	}
+	return func(v Value) bool {
+		return v.Valid && compareValues(v, lo) >= 0 && compareValues(v, hi) <= 0
+	}, nil
+}
+
+func inCondition(ct ColumnType, field, rawArgs string) (fieldMatch, error) {
+	parts := strings.Split(rawArgs, ",")
+
+	if ct.Kind == KindString {
+		set := make(map[string]bool, len(parts))
+		for _, part := range parts {
+			set[strings.TrimSpace(part)] = true
+		}
+		return func(v Value) bool { return set[v.Str] }, nil
+	}
+
+	operands := make([]Value, len(parts))
+	for i, part := range parts {
+		operand := parseValue(strings.TrimSpace(part), ct)
+		if !operand.Valid {
+			return nil, fmt.Errorf("value %q in in() is not a valid value for field %q", part, field)
+		}
+		operands[i] = operand
+	}
+	return func(v Value) bool {
+		if !v.Valid {
+			return false
+		}
+		for _, operand := range operands {
+			if compareValues(v, operand) == 0 {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
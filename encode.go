@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Encoder renders a stream of Records to an output format. WriteHeader is
+// called once with the fields to print, in order, before any WriteRow.
+type Encoder interface {
+	WriteHeader(fields []string) error
+	WriteRow(record Record) error
+	Close() error
+}
+
+// newEncoder builds the Encoder for the named -output format. csv/tsv
+// reuse delimiter so CSV input can round-trip losslessly back out as CSV.
+func newEncoder(format string, out io.Writer, delimiter rune) (Encoder, error) {
+	switch format {
+	case "", "csv":
+		return newDelimitedEncoder(out, delimiter), nil
+	case "tsv":
+		return newDelimitedEncoder(out, '\t'), nil
+	case "json":
+		return &jsonEncoder{w: out}, nil
+	case "jsonl":
+		return &jsonlEncoder{w: out}, nil
+	case "md":
+		return &mdEncoder{w: out}, nil
+	default:
+		return nil, fmt.Errorf("unknown -output format %q (want csv, tsv, json, jsonl, or md)", format)
+	}
+}
+
+// delimitedEncoder backs both the csv and tsv output formats.
+type delimitedEncoder struct {
+	w      *csv.Writer
+	fields []string
+}
+
+func newDelimitedEncoder(out io.Writer, delimiter rune) *delimitedEncoder {
+	w := csv.NewWriter(out)
+	w.Comma = delimiter
+	return &delimitedEncoder{w: w}
+}
+
+func (e *delimitedEncoder) WriteHeader(fields []string) error {
+	e.fields = fields
+	return e.w.Write(fields)
+}
+
+func (e *delimitedEncoder) WriteRow(record Record) error {
+	row := make([]string, len(e.fields))
+	for i, field := range e.fields {
+		// Str is the original source text; unlike String(), it isn't
+		// reformatted by kind, so csv/tsv round-trip losslessly (no
+		// "007" -> "7" or "5.0" -> "5" corruption).
+		row[i] = record.Data[field].Str
+	}
+	return e.w.Write(row)
+}
+
+func (e *delimitedEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonEncoder renders one JSON array of objects.
+type jsonEncoder struct {
+	w      io.Writer
+	fields []string
+	first  bool
+}
+
+func (e *jsonEncoder) WriteHeader(fields []string) error {
+	e.fields = fields
+	e.first = true
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonEncoder) WriteRow(record Record) error {
+	if !e.first {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.first = false
+	return writeJSONObject(e.w, e.fields, record)
+}
+
+func (e *jsonEncoder) Close() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+// jsonlEncoder renders one compact JSON object per line.
+type jsonlEncoder struct {
+	w      io.Writer
+	fields []string
+}
+
+func (e *jsonlEncoder) WriteHeader(fields []string) error {
+	e.fields = fields
+	return nil
+}
+
+func (e *jsonlEncoder) WriteRow(record Record) error {
+	if err := writeJSONObject(e.w, e.fields, record); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "\n")
+	return err
+}
+
+func (e *jsonlEncoder) Close() error { return nil }
+
+// writeJSONObject writes {"field":value,...} with fields in the given
+// order, preserving -select-fields order (encoding/json would otherwise
+// alphabetize a map's keys).
+func writeJSONObject(w io.Writer, fields []string, record Record) error {
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return err
+	}
+	for i, field := range fields {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		keyBytes, err := json.Marshal(field)
+		if err != nil {
+			return err
+		}
+		valBytes, err := json.Marshal(record.Data[field].jsonValue())
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s:%s", keyBytes, valBytes); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "}")
+	return err
+}
+
+// mdEncoder renders a Markdown table: a header row, a "---" separator
+// row, and one row per record. Cells are not padded to a common column
+// width, since doing so would mean buffering every row before writing
+// the first one; GFM renders the table correctly either way.
+type mdEncoder struct {
+	w      io.Writer
+	fields []string
+}
+
+func (e *mdEncoder) WriteHeader(fields []string) error {
+	e.fields = fields
+	if _, err := fmt.Fprintf(e.w, "| %s |\n", strings.Join(fields, " | ")); err != nil {
+		return err
+	}
+	seps := make([]string, len(fields))
+	for i := range seps {
+		seps[i] = "---"
+	}
+	_, err := fmt.Fprintf(e.w, "| %s |\n", strings.Join(seps, " | "))
+	return err
+}
+
+func (e *mdEncoder) WriteRow(record Record) error {
+	cells := make([]string, len(e.fields))
+	for i, field := range e.fields {
+		cells[i] = escapeMarkdownCell(record.Data[field].String())
+	}
+	_, err := fmt.Fprintf(e.w, "| %s |\n", strings.Join(cells, " | "))
+	return err
+}
+
+func (e *mdEncoder) Close() error { return nil }
+
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return s
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Parses filters into AND and OR conditions, with field validation. Filter
+// values are patterns or comparisons (see parseFieldCondition), not plain
+// equality checks, so e.g. "Företag,Kal*", "Company,!Acme*",
+// "Email,~^admin@", or "Age,>=30" all work.
+func parseFilters(filter string, fieldSet map[string]bool, types map[string]ColumnType) (map[string][]fieldMatch, map[string][]fieldMatch) {
+	andFilters := make(map[string][]fieldMatch)
+	orFilters := make(map[string][]fieldMatch)
+
+	if filter == "" {
+		return andFilters, orFilters
+	}
+
+	// Check for OR conditions
+	orRegex := regexp.MustCompile(`or\(([^)]+)\)`)
+	orMatches := orRegex.FindStringSubmatch(filter)
+	if len(orMatches) > 1 {
+		orParts := strings.Split(orMatches[1], ";")
+		for _, part := range orParts {
+			kv := strings.SplitN(part, ",", 2)
+			if len(kv) == 2 {
+				field, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+				if !fieldSet[field] {
+					fmt.Printf("Error: Field '%s' does not exist in the CSV file.\n", field)
+					os.Exit(2)
+				}
+				m, err := parseFieldCondition(field, value, types)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(2)
+				}
+				orFilters[field] = append(orFilters[field], m)
+			}
+		}
+		// Remove the OR condition from the main filter string
+		filter = orRegex.ReplaceAllString(filter, "")
+	}
+
+	// Process AND conditions
+	parts := strings.Split(filter, ";")
+	for _, part := range parts {
+		kv := strings.SplitN(part, ",", 2)
+		if len(kv) == 2 {
+			field, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+			if !fieldSet[field] {
+				fmt.Printf("Error: Field '%s' does not exist in the CSV file.\n", field)
+				os.Exit(2)
+			}
+			m, err := parseFieldCondition(field, value, types)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(2)
+			}
+			andFilters[field] = append(andFilters[field], m)
+		}
+	}
+
+	return andFilters, orFilters
+}
+
+// filterRecords consumes Records from in, applying the AND/OR conditions,
+// and emits the matching ones on the returned channel. This lets filtering
+// run as one stage of a streaming pipeline instead of buffering the whole
+// file in memory.
+func filterRecords(in <-chan Record, andFilters map[string][]fieldMatch, orFilters map[string][]fieldMatch) <-chan Record {
+	out := make(chan Record)
+
+	go func() {
+		defer close(out)
+		for record := range in {
+			if recordMatches(record, andFilters, orFilters) {
+				out <- record
+			}
+		}
+	}()
+
+	return out
+}
+
+// recordMatches reports whether a single record satisfies the AND/OR
+// conditions.
+func recordMatches(record Record, andFilters map[string][]fieldMatch, orFilters map[string][]fieldMatch) bool {
+	// Check AND filters
+	for field, matches := range andFilters {
+		for _, match := range matches {
+			if !match(record.Data[field]) {
+				return false
+			}
+		}
+	}
+
+	// Check OR filters (only if the AND conditions matched)
+	if len(orFilters) > 0 {
+		for field, matches := range orFilters {
+			for _, match := range matches {
+				if match(record.Data[field]) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,13 @@
+package main
+
+import "strings"
+
+// Formats selected fields of a record into a string, rendering each value
+// according to its inferred or declared type.
+func formatSelectedFields(record Record, fields []string) string {
+	var output []string
+	for _, field := range fields {
+		output = append(output, record.Data[field].String())
+	}
+	return strings.Join(output, ", ")
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func main() {
+	// Command-line arguments
+	fileName := flag.String("file", "", `Path(s) to the CSV file(s): a single path, a comma-separated list, or a glob (e.g. "logs/*.csv")`)
+	filter := flag.String("filter", "", `Filters in the format: "or(Företag,Kal*;Företag,Olle)" or "Företag,Kal*;Stad,Stockholm". Values are glob patterns by default; prefix with "!" to negate or "~" for a regex`)
+	selectFields := flag.String("select-fields", "", "Comma-separated list of field names to print (optional, defaults to all fields)")
+	unique := flag.Bool("unique", false, "Ensure output lines are unique")
+	listFields := flag.Bool("list-fields", false, "List available fields in the CSV file")
+	delimiter := flag.String("delimiter", ",", "Field delimiter character")
+	comment := flag.String("comment", "", "Lines beginning with this character are ignored as comments (disabled by default)")
+	fieldsPerRecord := flag.Int("fields-per-record", 0, "Expected number of fields per row (0 autodetects from the header, -1 allows a variable number)")
+	trimLeadingSpace := flag.Bool("trim-leading-space", false, "Trim leading whitespace from fields")
+	encodingName := flag.String("encoding", "utf8", "Source file encoding: utf8, gbk, or latin1")
+	lazyQuotes := flag.Bool("lazy-quotes", false, "Relax the CSV quoting rules to accept malformed quotes")
+	formatSchema := flag.String("format", "", `Positional schema for headerless CSVs, e.g. "$time $host $status"`)
+	headerMode := flag.String("header-mode", "strict", "How to reconcile headers across multiple -file matches: strict (require an exact match) or union (fill missing columns with empty strings)")
+	typesSpec := flag.String("types", "", `Declared column types, e.g. "Age:int,Price:float,JoinDate:date(2006-01-02),Active:bool" (omitted columns are inferred from a sample of rows)`)
+	groupBy := flag.String("group-by", "", "Comma-separated fields to group by before applying -agg")
+	agg := flag.String("agg", "", `Aggregates to compute after filtering, e.g. "count();sum(Price);avg(Price);min(Age);max(Age)"`)
+	outputFormat := flag.String("output", "csv", "Output format: csv, tsv, json, jsonl, or md")
+	outPath := flag.String("out", "", "Output file path (default: stdout)")
+	flag.Parse()
+
+	// Ensure a file is provided
+	if *fileName == "" {
+		fmt.Println("Usage: go run . -file=filename.csv [-list-fields] [-filter='or(Företag,Kalle;Företag,Olle)'] [-select-fields=field1,field2,...] [-unique]")
+		os.Exit(1)
+	}
+
+	// Resolve -file to one or more paths (comma-separated and/or globs)
+	// and union their rows, reading files in order so memory stays
+	// bounded no matter how many match.
+	files, err := resolveFiles(*fileName)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(files) == 0 {
+		fmt.Println("No files matched -file.")
+		os.Exit(1)
+	}
+
+	opts := csvOptions{
+		delimiter:        runeFromFlag(*delimiter, ','),
+		comment:          runeFromFlag(*comment, 0),
+		hasComment:       *comment != "",
+		fieldsPerRecord:  *fieldsPerRecord,
+		trimLeadingSpace: *trimLeadingSpace,
+		lazyQuotes:       *lazyQuotes,
+		encoding:         *encodingName,
+	}
+
+	// Determine the unified header: either a declared -format schema, for
+	// headerless files, or the reconciled header row(s) of the CSV files
+	// themselves. Either way it ends with the implicit _source column.
+	header, err := buildUnifiedHeader(files, opts, *formatSchema, *headerMode)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fieldSet := make(map[string]bool, len(header))
+	for _, field := range header {
+		fieldSet[field] = true
+	}
+
+	// If the user requested to list fields, print them and exit
+	if *listFields {
+		fmt.Println("Available fields:")
+		for _, field := range header {
+			fmt.Println(field)
+		}
+		os.Exit(0)
+	}
+
+	var rows rowReader = &multiFileReader{
+		files:         files,
+		unifiedFields: header[:len(header)-1],
+		opts:          opts,
+		formatSchema:  *formatSchema,
+	}
+
+	// Determine column types: either declared via -types, or inferred by
+	// scanning a bounded sample of rows ahead of the streaming pipeline.
+	var types map[string]ColumnType
+	if *typesSpec != "" {
+		types, err = parseTypes(*typesSpec)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		sample, err := readSample(rows, typeSampleSize)
+		if err != nil {
+			fmt.Printf("Error reading file: %v\n", err)
+			os.Exit(1)
+		}
+		types = inferTypes(header, sample)
+		rows = &bufferedRowReader{rows: sample, rest: rows}
+	}
+
+	// Parse the filters
+	andFilters, orFilters := parseFilters(*filter, fieldSet, types)
+
+	// Stream rows through the filter/select pipeline instead of reading
+	// the whole file into memory, so multi-GB CSVs stay bounded.
+	records := streamRecords(rows, header, types)
+	filteredRecords := filterRecords(records, andFilters, orFilters)
+
+	// When -agg is set, reduce the filtered records into one row per
+	// group-by key (or a single summary row with no -group-by), and print
+	// from that in place of the raw filtered stream.
+	var groupByFields []string
+	var aggregated []Record
+	if *agg != "" {
+		if *groupBy != "" {
+			groupByFields = strings.Split(*groupBy, ",")
+			for _, field := range groupByFields {
+				if !fieldSet[field] {
+					fmt.Printf("Error: Field '%s' does not exist in the CSV file.\n", field)
+					os.Exit(2)
+				}
+			}
+		}
+		specs, err := parseAggSpecs(*agg)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(2)
+		}
+		for _, spec := range specs {
+			if spec.Field != "" && !fieldSet[spec.Field] {
+				fmt.Printf("Error: Field '%s' does not exist in the CSV file.\n", spec.Field)
+				os.Exit(2)
+			}
+		}
+		if err := validateAggSpecs(specs, types); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(2)
+		}
+		aggregated = aggregateRecords(filteredRecords, groupByFields, specs)
+
+		header = append([]string{}, groupByFields...)
+		for _, spec := range specs {
+			header = append(header, spec.Label())
+		}
+		fieldSet = make(map[string]bool, len(header))
+		for _, field := range header {
+			fieldSet[field] = true
+		}
+	}
+
+	// Validate `-select-fields` argument against the final field set
+	// (post-aggregation, if -agg was given).
+	var fieldsToPrint []string
+	if *selectFields == "" {
+		fieldsToPrint = header
+	} else {
+		fieldsToPrint = strings.Split(*selectFields, ",")
+		for _, field := range fieldsToPrint {
+			if !fieldSet[field] {
+				fmt.Printf("Error: Field '%s' does not exist in the CSV file.\n", field)
+				os.Exit(2)
+			}
+		}
+	}
+
+	// Open the output destination and encoder.
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		outFile, err := os.Create(*outPath)
+		if err != nil {
+			fmt.Printf("Error creating output file: %v\n", err)
+			os.Exit(1)
+		}
+		defer outFile.Close()
+		out = outFile
+	}
+
+	encoder, err := newEncoder(*outputFormat, out, runeFromFlag(*delimiter, ','))
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := encoder.WriteHeader(fieldsToPrint); err != nil {
+		fmt.Printf("Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+
+	printedLines := make(map[string]bool)
+	writeRecord := func(record Record) {
+		if *unique {
+			key := formatSelectedFields(record, fieldsToPrint)
+			if printedLines[key] {
+				return
+			}
+			printedLines[key] = true
+		}
+		if err := encoder.WriteRow(record); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *agg != "" {
+		for _, record := range aggregated {
+			writeRecord(record)
+		}
+	} else {
+		for record := range filteredRecords {
+			writeRecord(record)
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		fmt.Printf("Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// rowReader is satisfied by *csv.Reader and by bufferedRowReader, so the
+// streaming pipeline doesn't care whether rows were sampled ahead of time
+// for type inference.
+type rowReader interface {
+	Read() ([]string, error)
+}
+
+// bufferedRowReader replays rows buffered during type inference before
+// falling through to the underlying reader, so no row is read twice.
+type bufferedRowReader struct {
+	rows [][]string
+	rest rowReader
+}
+
+func (b *bufferedRowReader) Read() ([]string, error) {
+	if len(b.rows) > 0 {
+		row := b.rows[0]
+		b.rows = b.rows[1:]
+		return row, nil
+	}
+	return b.rest.Read()
+}
+
+// readSample reads up to n rows from reader, stopping early on EOF, for
+// type inference to scan without loading the whole file into memory.
+func readSample(reader rowReader, n int) ([][]string, error) {
+	var sample [][]string
+	for i := 0; i < n; i++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		sample = append(sample, row)
+	}
+	return sample, nil
+}
+
+// streamRecords reads rows from reader one at a time and emits them as
+// Records on the returned channel, mapped onto header and typed according
+// to types. This is the first stage of the pipeline, so later stages
+// (filter, select, future aggregation) can be composed without buffering
+// the whole file.
+func streamRecords(reader rowReader, header []string, types map[string]ColumnType) <-chan Record {
+	out := make(chan Record)
+
+	go func() {
+		defer close(out)
+		for {
+			row, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				fmt.Printf("Error reading file: %v\n", err)
+				os.Exit(1)
+			}
+			out <- rowToRecord(header, row, types)
+		}
+	}()
+
+	return out
+}
+
+// runeFromFlag extracts the first rune of s, falling back to def for an
+// empty string.
+func runeFromFlag(s string, def rune) rune {
+	for _, r := range s {
+		return r
+	}
+	return def
+}
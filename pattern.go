@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// matchFunc reports whether a field value satisfies a compiled filter
+// pattern.
+type matchFunc func(value string) bool
+
+// compilePattern turns a filter pattern into a matchFunc. Patterns are
+// glob expressions matched with filepath.Match by default (e.g. "Kal*"),
+// a "~" prefix switches to regular-expression matching (e.g. "~^admin@"),
+// and a leading "!" negates the result either way.
+func compilePattern(pattern string) (matchFunc, error) {
+	negate := strings.HasPrefix(pattern, "!")
+	if negate {
+		pattern = pattern[1:]
+	}
+
+	var m matchFunc
+	switch {
+	case strings.HasPrefix(pattern, "~"):
+		re, err := regexp.Compile(pattern[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex pattern %q: %w", pattern, err)
+		}
+		m = re.MatchString
+	default:
+		// Validate the pattern up front so a malformed glob (filepath.ErrBadPattern)
+		// is reported once, at parse time, instead of on every row.
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+		m = func(value string) bool {
+			matched, _ := filepath.Match(pattern, value)
+			return matched
+		}
+	}
+
+	if negate {
+		inner := m
+		m = func(value string) bool { return !inner(value) }
+	}
+
+	return m, nil
+}
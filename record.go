@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// Record represents a single parsed CSV row, keyed by field name, with
+// each cell carrying its inferred or declared type.
+type Record struct {
+	Data map[string]Value
+}
+
+// decodedReader wraps r so that its bytes are transcoded from the named
+// source encoding to UTF-8 before reaching the CSV reader. "utf8" (the
+// default) and "" are passed through unchanged.
+func decodedReader(r io.Reader, encoding string) (io.Reader, error) {
+	switch strings.ToLower(encoding) {
+	case "", "utf8", "utf-8":
+		return r, nil
+	case "gbk":
+		return transform.NewReader(r, simplifiedchinese.GBK.NewDecoder()), nil
+	case "latin1":
+		return transform.NewReader(r, charmap.ISO8859_1.NewDecoder()), nil
+	default:
+		return nil, fmt.Errorf("unsupported -encoding %q (want utf8, gbk, or latin1)", encoding)
+	}
+}
+
+// parseFormatSchema turns a declared schema such as "$time $host $status"
+// into positional field names ("time", "host", "status"), for use as a
+// synthetic header when the CSV itself has none.
+func parseFormatSchema(schema string) []string {
+	fields := strings.Fields(schema)
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = strings.TrimPrefix(field, "$")
+	}
+	return names
+}
+
+// rowToRecord maps a raw CSV row onto header, producing a Record whose
+// cells are parsed according to types. Extra trailing values beyond
+// len(header) are dropped.
+func rowToRecord(header []string, row []string, types map[string]ColumnType) Record {
+	record := Record{Data: make(map[string]Value, len(header))}
+	for i, value := range row {
+		if i < len(header) {
+			record.Data[header[i]] = parseValue(value, types[header[i]])
+		}
+	}
+	return record
+}
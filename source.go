@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sourceField is the implicit pseudo-column added to every row, holding
+// the path of the file it came from.
+const sourceField = "_source"
+
+// csvOptions bundles the csv.Reader settings controlled by flags, so they
+// can be applied consistently to every file a multi-file run opens.
+type csvOptions struct {
+	delimiter        rune
+	comment          rune
+	hasComment       bool
+	fieldsPerRecord  int
+	trimLeadingSpace bool
+	lazyQuotes       bool
+	encoding         string
+}
+
+func applyCSVOptions(reader *csv.Reader, opts csvOptions) {
+	reader.Comma = opts.delimiter
+	if opts.hasComment {
+		reader.Comment = opts.comment
+	}
+	reader.FieldsPerRecord = opts.fieldsPerRecord
+	reader.TrimLeadingSpace = opts.trimLeadingSpace
+	reader.LazyQuotes = opts.lazyQuotes
+}
+
+// resolveFiles expands the comma-separated paths/globs in a -file value
+// into an ordered list of file paths. A token with no glob matches is
+// kept as a literal path, so opening it later reports a clear "file not
+// found" instead of silently vanishing.
+func resolveFiles(spec string) ([]string, error) {
+	var files []string
+	for _, token := range strings.Split(spec, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		matches, err := filepath.Glob(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -file pattern %q: %w", token, err)
+		}
+		if len(matches) == 0 {
+			matches = []string{token}
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// peekHeader opens path just long enough to read its header row.
+func peekHeader(path string, opts csvOptions) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	decoded, err := decodedReader(file, opts.encoding)
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(decoded)
+	applyCSVOptions(reader, opts)
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return header, nil
+}
+
+// buildUnifiedHeader determines the header for the union of all files,
+// plus the trailing _source pseudo-column. With a -format schema every
+// file is headerless and uses that schema directly. Otherwise, each
+// file's own header row is read and reconciled per headerMode: "strict"
+// (the default) requires them to match exactly, "union" computes the
+// union of all columns in first-seen order, filling absent ones with "".
+func buildUnifiedHeader(files []string, opts csvOptions, formatSchema, headerMode string) ([]string, error) {
+	if formatSchema != "" {
+		return append(parseFormatSchema(formatSchema), sourceField), nil
+	}
+
+	fileHeaders := make([][]string, len(files))
+	for i, path := range files {
+		header, err := peekHeader(path, opts)
+		if err != nil {
+			return nil, err
+		}
+		fileHeaders[i] = header
+	}
+
+	switch headerMode {
+	case "", "strict":
+		first := fileHeaders[0]
+		for i, header := range fileHeaders[1:] {
+			if !equalStrings(header, first) {
+				return nil, fmt.Errorf("%s: header %v does not match %s's header %v (use -header-mode=union to allow differing columns)", files[i+1], header, files[0], first)
+			}
+		}
+		return append(append([]string{}, first...), sourceField), nil
+	case "union":
+		var unified []string
+		seen := make(map[string]bool)
+		for _, header := range fileHeaders {
+			for _, field := range header {
+				if !seen[field] {
+					seen[field] = true
+					unified = append(unified, field)
+				}
+			}
+		}
+		return append(unified, sourceField), nil
+	default:
+		return nil, fmt.Errorf("unknown -header-mode %q (want strict or union)", headerMode)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// multiFileReader streams rows from files in order, translating each
+// row's columns into the unified header's order (filling missing columns
+// with "") and appending the originating path as the _source column. It
+// implements rowReader, so it plugs into the same streaming pipeline as a
+// single *csv.Reader.
+type multiFileReader struct {
+	files         []string
+	unifiedFields []string // unified header without the trailing _source
+	opts          csvOptions
+	formatSchema  string
+
+	idx      int
+	file     *os.File
+	reader   *csv.Reader
+	colIndex []int // unifiedFields[i] -> column index in the current file's row, or -1
+	source   string
+}
+
+func (m *multiFileReader) Read() ([]string, error) {
+	for {
+		if m.reader == nil {
+			if m.idx >= len(m.files) {
+				return nil, io.EOF
+			}
+			if err := m.openNext(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		row, err := m.reader.Read()
+		if err == io.EOF {
+			m.file.Close()
+			m.reader = nil
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.source, err)
+		}
+		return m.translate(row), nil
+	}
+}
+
+func (m *multiFileReader) openNext() error {
+	path := m.files[m.idx]
+	m.idx++
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	decoded, err := decodedReader(file, m.opts.encoding)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	reader := csv.NewReader(decoded)
+	applyCSVOptions(reader, m.opts)
+
+	if m.formatSchema == "" {
+		fileHeader, err := reader.Read()
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		m.colIndex = indexMap(m.unifiedFields, fileHeader)
+	} else {
+		m.colIndex = identityMap(len(m.unifiedFields))
+	}
+
+	m.file = file
+	m.reader = reader
+	m.source = path
+	return nil
+}
+
+func (m *multiFileReader) translate(row []string) []string {
+	out := make([]string, len(m.unifiedFields)+1)
+	for i, col := range m.colIndex {
+		if col >= 0 && col < len(row) {
+			out[i] = row[col]
+		}
+	}
+	out[len(m.unifiedFields)] = m.source
+	return out
+}
+
+// indexMap maps each unified field to its column index within fileHeader,
+// or -1 if fileHeader doesn't have that column (union mode).
+func indexMap(unifiedFields, fileHeader []string) []int {
+	pos := make(map[string]int, len(fileHeader))
+	for i, field := range fileHeader {
+		pos[field] = i
+	}
+	idx := make([]int, len(unifiedFields))
+	for i, field := range unifiedFields {
+		if p, ok := pos[field]; ok {
+			idx[i] = p
+		} else {
+			idx[i] = -1
+		}
+	}
+	return idx
+}
+
+func identityMap(n int) []int {
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	return idx
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the inferred or declared type of a column.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindFloat
+	KindDate
+	KindBool
+)
+
+// ColumnType describes how a column's raw text should be parsed. Layout
+// only applies to KindDate, defaulting to time.RFC3339 when empty.
+type ColumnType struct {
+	Kind   Kind
+	Layout string
+}
+
+// Value is a single typed cell. Str always holds the original text, so
+// formatting and string-based filters keep working even when Valid is
+// false (the text didn't parse as the column's declared/inferred kind).
+type Value struct {
+	Kind   Kind
+	Str    string
+	Int    int64
+	Float  float64
+	Time   time.Time
+	Bool   bool
+	Layout string
+	Valid  bool
+}
+
+// String renders a Value back to text, formatting typed values according
+// to their kind rather than just echoing the source text.
+func (v Value) String() string {
+	if !v.Valid {
+		return v.Str
+	}
+	switch v.Kind {
+	case KindInt:
+		return strconv.FormatInt(v.Int, 10)
+	case KindFloat:
+		return strconv.FormatFloat(v.Float, 'g', -1, 64)
+	case KindBool:
+		return strconv.FormatBool(v.Bool)
+	case KindDate:
+		layout := v.Layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return v.Time.Format(layout)
+	default:
+		return v.Str
+	}
+}
+
+// jsonValue returns v as a native Go value suitable for json.Marshal, so
+// numeric/bool columns come out as JSON numbers/booleans instead of
+// quoted strings.
+func (v Value) jsonValue() interface{} {
+	if !v.Valid {
+		return v.Str
+	}
+	switch v.Kind {
+	case KindInt:
+		return v.Int
+	case KindFloat:
+		return v.Float
+	case KindBool:
+		return v.Bool
+	default:
+		return v.String()
+	}
+}
+
+// parseValue converts raw text to a Value of the given column type. If the
+// text doesn't parse as that kind, Valid is false and Str still carries
+// the original text, so the row isn't dropped outright.
+func parseValue(raw string, ct ColumnType) Value {
+	switch ct.Kind {
+	case KindString:
+		return Value{Kind: KindString, Str: raw, Valid: true}
+	case KindInt:
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return Value{Kind: KindInt, Str: raw, Int: n, Valid: true}
+		}
+	case KindFloat:
+		if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			return Value{Kind: KindFloat, Str: raw, Float: f, Valid: true}
+		}
+	case KindBool:
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return Value{Kind: KindBool, Str: raw, Bool: b, Valid: true}
+		}
+	case KindDate:
+		layout := ct.Layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		if t, err := time.Parse(layout, raw); err == nil {
+			return Value{Kind: KindDate, Str: raw, Time: t, Layout: layout, Valid: true}
+		}
+	}
+	return Value{Kind: ct.Kind, Str: raw}
+}
+
+// parseTypes parses a -types flag spec such as
+// "Age:int,Price:float,JoinDate:date(2006-01-02),Active:bool" into a
+// per-column ColumnType map.
+func parseTypes(spec string) (map[string]ColumnType, error) {
+	types := make(map[string]ColumnType)
+	if spec == "" {
+		return types, nil
+	}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		nameKind := strings.SplitN(part, ":", 2)
+		if len(nameKind) != 2 {
+			return nil, fmt.Errorf("invalid -types entry %q (want Field:kind)", part)
+		}
+		name := strings.TrimSpace(nameKind[0])
+		kind, layout, err := parseKindSpec(strings.TrimSpace(nameKind[1]))
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", name, err)
+		}
+		types[name] = ColumnType{Kind: kind, Layout: layout}
+	}
+	return types, nil
+}
+
+func parseKindSpec(spec string) (Kind, string, error) {
+	if strings.HasPrefix(spec, "date(") && strings.HasSuffix(spec, ")") {
+		return KindDate, spec[len("date(") : len(spec)-1], nil
+	}
+	switch spec {
+	case "string":
+		return KindString, "", nil
+	case "int":
+		return KindInt, "", nil
+	case "float":
+		return KindFloat, "", nil
+	case "date":
+		return KindDate, time.RFC3339, nil
+	case "bool":
+		return KindBool, "", nil
+	}
+	return KindString, "", fmt.Errorf("unknown type %q (want string, int, float, date, date(layout), or bool)", spec)
+}
+
+// typeSampleSize bounds how many rows inferColumnTypes scans per column,
+// so inference still runs in bounded memory ahead of the streaming pipeline.
+const typeSampleSize = 1000
+
+// inferTypes infers a ColumnType per header field by scanning sample, a
+// prefix of the data rows: all parseable as int -> int, else float, else
+// a consistent date layout -> date, else bool, else string.
+func inferTypes(header []string, sample [][]string) map[string]ColumnType {
+	types := make(map[string]ColumnType, len(header))
+	for col, field := range header {
+		values := make([]string, 0, len(sample))
+		for _, row := range sample {
+			if col < len(row) && strings.TrimSpace(row[col]) != "" {
+				values = append(values, row[col])
+			}
+		}
+		types[field] = inferColumnType(values)
+	}
+	return types
+}
+
+var dateLayouts = []string{time.RFC3339, "2006-01-02"}
+
+func inferColumnType(values []string) ColumnType {
+	if len(values) == 0 {
+		return ColumnType{Kind: KindString}
+	}
+	if allMatch(values, func(v string) bool { _, err := strconv.ParseInt(v, 10, 64); return err == nil }) {
+		return ColumnType{Kind: KindInt}
+	}
+	if allMatch(values, func(v string) bool { _, err := strconv.ParseFloat(v, 64); return err == nil }) {
+		return ColumnType{Kind: KindFloat}
+	}
+	for _, layout := range dateLayouts {
+		if allMatch(values, func(v string) bool { _, err := time.Parse(layout, v); return err == nil }) {
+			return ColumnType{Kind: KindDate, Layout: layout}
+		}
+	}
+	if allMatch(values, func(v string) bool { _, err := strconv.ParseBool(v); return err == nil }) {
+		return ColumnType{Kind: KindBool}
+	}
+	return ColumnType{Kind: KindString}
+}
+
+func allMatch(values []string, ok func(string) bool) bool {
+	for _, v := range values {
+		if !ok(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareValues orders two Values of the same Kind, returning <0, 0, or
+// >0 the way strings.Compare does.
+func compareValues(a, b Value) int {
+	switch a.Kind {
+	case KindInt:
+		switch {
+		case a.Int < b.Int:
+			return -1
+		case a.Int > b.Int:
+			return 1
+		default:
+			return 0
+		}
+	case KindFloat:
+		switch {
+		case a.Float < b.Float:
+			return -1
+		case a.Float > b.Float:
+			return 1
+		default:
+			return 0
+		}
+	case KindDate:
+		switch {
+		case a.Time.Before(b.Time):
+			return -1
+		case a.Time.After(b.Time):
+			return 1
+		default:
+			return 0
+		}
+	case KindBool:
+		ai, bi := boolToInt(a.Bool), boolToInt(b.Bool)
+		return ai - bi
+	default:
+		return strings.Compare(a.Str, b.Str)
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}